@@ -0,0 +1,119 @@
+package api
+
+import (
+	"flag"
+	"regexp"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/weaveworks/common/middleware"
+
+	"github.com/cortexproject/cortex/pkg/util/runtimeconfig"
+)
+
+// Config configures the HTTP API exposed by Cortex, on top of what the individual
+// component configs already provide.
+type Config struct {
+	// ServerPrefix is prepended to PrometheusHTTPPrefix and LegacyHTTPPrefix to build the
+	// absolute paths that the querier's routes match.
+	ServerPrefix string `yaml:"-"`
+
+	PrometheusHTTPPrefix string `yaml:"prometheus_http_prefix"`
+	LegacyHTTPPrefix     string `yaml:"legacy_http_prefix"`
+
+	// ExternalLabels and ScrapeConfigs are surfaced verbatim through the synthesized
+	// config.Config returned by the querier's /api/v1/status/config endpoint.
+	ExternalLabels labels.Labels          `yaml:"-"`
+	ScrapeConfigs  []*config.ScrapeConfig `yaml:"-"`
+
+	// StorageRetention is reported as-is by the querier's /api/v1/status/runtimeinfo endpoint.
+	StorageRetention string `yaml:"-"`
+
+	// CORSOrigin is matched against the request's Origin header by the querier's CORS
+	// middleware; it defaults to matching nothing, so CORS is off unless configured.
+	CORSOrigin *regexp.Regexp `yaml:"-"`
+}
+
+// RegisterFlags registers CLI flags for the API config.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&cfg.PrometheusHTTPPrefix, "http.prometheus-http-prefix", "/prometheus", "HTTP path prefix for Prometheus API.")
+	f.StringVar(&cfg.LegacyHTTPPrefix, "http.legacy-http-prefix", "/api/prom", "HTTP path prefix for legacy Prometheus API.")
+	cfg.CORSOrigin = regexp.MustCompile("^$")
+	f.Var(regexpFlagValue{&cfg.CORSOrigin}, "server.cors-origin", "Regex for CORS origin. It is fully anchored. Match nothing by default.")
+}
+
+// regexpFlagValue adapts a *regexp.Regexp field to flag.Value, mirroring Prometheus's
+// web.cors.origin flag.
+type regexpFlagValue struct {
+	re **regexp.Regexp
+}
+
+func (r regexpFlagValue) String() string {
+	if r.re == nil || *r.re == nil {
+		return ""
+	}
+	return (*r.re).String()
+}
+
+func (r regexpFlagValue) Set(s string) error {
+	re, err := regexp.Compile("^(?:" + s + ")$")
+	if err != nil {
+		return err
+	}
+	*r.re = re
+	return nil
+}
+
+// API registers the debug/admin HTTP endpoints served alongside the Prometheus-compatible
+// querier API, and keeps the landing page's index of them up to date.
+type API struct {
+	router    *mux.Router
+	indexPage *IndexPageContent
+}
+
+// NewAPI creates an API that registers its routes on router and its links on indexPage, and
+// registers the index page itself at GET /, including its ?format=json discovery mode.
+func NewAPI(httpPathPrefix string, router *mux.Router, indexPage *IndexPageContent) *API {
+	router.Path("/").Methods("GET").Handler(indexHandler(httpPathPrefix, indexPage))
+
+	return &API{router: router, indexPage: indexPage}
+}
+
+// RegisterConfig registers the /config and /config/hash endpoints, which expose the actual
+// running configuration and a hash of its canonical form, respectively.
+func (a *API) RegisterConfig(actualCfg, defaultCfg interface{}) {
+	a.router.Path("/config").Methods("GET").Handler(configHandler(actualCfg, defaultCfg))
+	a.indexPage.AddLink(SectionAdminEndpoints, "/config", "Current configuration (including the default values).")
+
+	a.router.Path("/config/hash").Methods("GET").Handler(configHashHandler(actualCfg))
+	a.indexPage.AddLink(SectionAdminEndpoints, "/config/hash", "SHA256 hash of the canonicalized configuration.")
+}
+
+// RegisterRuntimeConfig registers the /runtime_config endpoint, and the POST /-/reload endpoint
+// that forces runtimeCfgManager to re-read its source immediately instead of waiting for its
+// next poll tick.
+func (a *API) RegisterRuntimeConfig(runtimeCfgManager *runtimeconfig.Manager) {
+	a.router.Path("/runtime_config").Methods("GET").Handler(runtimeConfigHandler(runtimeCfgManager))
+	a.indexPage.AddLink(SectionAdminEndpoints, "/runtime_config", "Current runtime configuration (incl. overrides).")
+
+	a.router.Path("/-/reload").Methods("POST").Handler(runtimeConfigReloadHandler(runtimeCfgManager))
+	a.indexPage.AddLink(SectionAdminEndpoints, "/-/reload", "Reload the runtime configuration.")
+}
+
+// RegisterTenantMetrics registers GET /api/v1/user_metrics behind authMiddleware, so that only
+// requests carrying a valid tenant ID ever reach the handler. Each tenant sees only their own
+// metrics, with the "user" label dropped.
+func (a *API) RegisterTenantMetrics(gatherer prometheus.Gatherer, authMiddleware middleware.Interface) {
+	a.router.Path("/api/v1/user_metrics").Methods("GET").Handler(authMiddleware.Wrap(TenantMetricsHandler(gatherer)))
+	a.indexPage.AddLink(SectionAdminEndpoints, "/api/v1/user_metrics", "Your tenant's metrics (single-tenant view).")
+}
+
+// RegisterAdminTenantMetrics registers GET /api/v1/all_tenants_metrics on adminRouter, which
+// must be the router bound to Cortex's internal admin server rather than the tenant-facing one:
+// unlike RegisterTenantMetrics, the response is the raw, unfiltered metric list for every
+// tenant, and carries no per-request authorization check of its own.
+func (a *API) RegisterAdminTenantMetrics(adminRouter *mux.Router, gatherer prometheus.Gatherer) {
+	adminRouter.Path("/api/v1/all_tenants_metrics").Methods("GET").Handler(AdminTenantMetricsHandler(gatherer))
+}
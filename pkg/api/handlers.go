@@ -2,23 +2,33 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"html/template"
 	"net/http"
+	"os"
 	"path"
 	"reflect"
 	"regexp"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/gorilla/mux"
 	"github.com/opentracing-contrib/go-stdlib/nethttp"
 	"github.com/opentracing/opentracing-go"
-	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/common/route"
+	"github.com/prometheus/common/version"
 	"github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/promql"
 	"github.com/prometheus/prometheus/storage"
@@ -31,6 +41,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/distributor"
 	"github.com/cortexproject/cortex/pkg/querier"
 	"github.com/cortexproject/cortex/pkg/querier/stats"
+	"github.com/cortexproject/cortex/pkg/tenant"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/runtimeconfig"
 )
@@ -42,42 +53,112 @@ const (
 
 func newIndexPageContent() *IndexPageContent {
 	return &IndexPageContent{
-		content: map[string]map[string]string{},
+		sections: map[string]*indexPageSection{},
 	}
 }
 
-// IndexPageContent is a map of sections to path -> description.
+// indexPageLink is a single entry, ordered by (weight, path).
+type indexPageLink struct {
+	path        string
+	description string
+	weight      int
+}
+
+// indexPageSection groups links under a heading, itself ordered by (weight, name).
+type indexPageSection struct {
+	description string
+	weight      int
+	links       map[string]*indexPageLink
+}
+
+// IndexPageContent is a map of sections to path -> description, rendered on the landing page.
 type IndexPageContent struct {
-	mu      sync.Mutex
-	content map[string]map[string]string
+	mu       sync.Mutex
+	sections map[string]*indexPageSection
 }
 
+func (pc *IndexPageContent) getOrCreateSection(name string) *indexPageSection {
+	s := pc.sections[name]
+	if s == nil {
+		s = &indexPageSection{links: map[string]*indexPageLink{}}
+		pc.sections[name] = s
+	}
+	return s
+}
+
+// AddLink adds a link to section with the default weight of 0.
 func (pc *IndexPageContent) AddLink(section, path, description string) {
+	pc.AddLinkWithWeight(section, path, description, 0)
+}
+
+// AddLinkWithWeight adds a link to section, sorted relative to the section's other links by
+// (weight, path).
+func (pc *IndexPageContent) AddLinkWithWeight(section, path, description string, weight int) {
 	pc.mu.Lock()
 	defer pc.mu.Unlock()
 
-	sectionMap := pc.content[section]
-	if sectionMap == nil {
-		sectionMap = make(map[string]string)
-		pc.content[section] = sectionMap
-	}
+	s := pc.getOrCreateSection(section)
+	s.links[path] = &indexPageLink{path: path, description: description, weight: weight}
+}
+
+// AddSection sets a section's description and its ordering relative to other sections, by
+// (weight, name). Sections are implicitly created by AddLink/AddLinkWithWeight, so this may be
+// called before or after any links have been added to it.
+func (pc *IndexPageContent) AddSection(name, description string, weight int) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	s := pc.getOrCreateSection(name)
+	s.description = description
+	s.weight = weight
+}
+
+// IndexPageRenderedLink is the stable, sorted shape of a link served to templates and to
+// GET /?format=json.
+type IndexPageRenderedLink struct {
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
 
-	sectionMap[path] = description
+// IndexPageRenderedSection is the stable, sorted shape of a section served to templates and to
+// GET /?format=json.
+type IndexPageRenderedSection struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description,omitempty"`
+	Links       []IndexPageRenderedLink `json:"links"`
 }
 
-func (pc *IndexPageContent) GetContent() map[string]map[string]string {
+// GetContent returns the sections and links sorted by (weight, name), so that the landing page
+// and ?format=json response have a stable order across requests.
+func (pc *IndexPageContent) GetContent() []IndexPageRenderedSection {
 	pc.mu.Lock()
 	defer pc.mu.Unlock()
 
-	result := map[string]map[string]string{}
-	for k, v := range pc.content {
-		sm := map[string]string{}
-		for smK, smV := range v {
-			sm[smK] = smV
+	sections := make([]IndexPageRenderedSection, 0, len(pc.sections))
+	for name, s := range pc.sections {
+		links := make([]IndexPageRenderedLink, 0, len(s.links))
+		for _, l := range s.links {
+			links = append(links, IndexPageRenderedLink{Path: l.path, Description: l.description})
 		}
-		result[k] = sm
+		sort.Slice(links, func(i, j int) bool {
+			li, lj := s.links[links[i].Path], s.links[links[j].Path]
+			if li.weight != lj.weight {
+				return li.weight < lj.weight
+			}
+			return li.path < lj.path
+		})
+
+		sections = append(sections, IndexPageRenderedSection{Name: name, Description: s.description, Links: links})
 	}
-	return result
+	sort.Slice(sections, func(i, j int) bool {
+		si, sj := pc.sections[sections[i].Name], pc.sections[sections[j].Name]
+		if si.weight != sj.weight {
+			return si.weight < sj.weight
+		}
+		return sections[i].Name < sections[j].Name
+	})
+
+	return sections
 }
 
 var indexPageTemplate = `
@@ -89,11 +170,12 @@ var indexPageTemplate = `
 	</head>
 	<body>
 		<h1>Cortex</h1>
-		{{ range $s, $links := . }}
-		<p>{{ $s }}</p>
+		{{ range . }}
+		<p>{{ .Name }}</p>
+		{{ if .Description }}<p>{{ .Description }}</p>{{ end }}
 		<ul>
-			{{ range $path, $desc := $links }}
-				<li><a href="{{ AddPathPrefix $path }}">{{ $desc }}</a></li>
+			{{ range .Links }}
+				<li><a href="{{ AddPathPrefix .Path }}">{{ .Description }}</a></li>
 			{{ end }}
 		</ul>
 		{{ end }}
@@ -110,8 +192,14 @@ func indexHandler(httpPathPrefix string, content *IndexPageContent) http.Handler
 	template.Must(templ.Parse(indexPageTemplate))
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := templ.Execute(w, content.GetContent())
-		if err != nil {
+		sections := content.GetContent()
+
+		if r.URL.Query().Get("format") == "json" {
+			util.WriteJSONResponse(w, sections)
+			return
+		}
+
+		if err := templ.Execute(w, sections); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
 	}
@@ -188,6 +276,50 @@ func diffConfig(defaultConfig, actualConfig map[interface{}]interface{}) (map[in
 	return output, nil
 }
 
+// canonicalConfigYAML returns the YAML encoding of cfg with map keys sorted, so that two
+// semantically identical configs always produce byte-identical output regardless of struct
+// field order.
+func canonicalConfigYAML(cfg interface{}) ([]byte, error) {
+	obj, err := yamlMarshalUnmarshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(obj)
+}
+
+// writeConfigResponse writes output as JSON when the request's Accept header asks for it,
+// defaulting to YAML for backwards compatibility with existing scrapers.
+func writeConfigResponse(w http.ResponseWriter, r *http.Request, output interface{}) {
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		util.WriteJSONResponse(w, jsonSafe(output))
+		return
+	}
+	util.WriteYAMLResponse(w, output)
+}
+
+// jsonSafe recursively rewrites the map[interface{}]interface{} values produced by
+// yamlMarshalUnmarshal (diffConfig and the "canonical" config mode) into map[string]interface{},
+// since encoding/json refuses to marshal a map with interface{} keys. Everything else is
+// returned unchanged.
+func jsonSafe(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[fmt.Sprintf("%v", key)] = jsonSafe(value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = jsonSafe(value)
+		}
+		return out
+	default:
+		return in
+	}
+}
+
 func configHandler(actualCfg interface{}, defaultCfg interface{}) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var output interface{}
@@ -212,13 +344,95 @@ func configHandler(actualCfg interface{}, defaultCfg interface{}) http.HandlerFu
 			}
 			output = diff
 
+		case "canonical":
+			canonicalObj, err := yamlMarshalUnmarshal(actualCfg)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			output = canonicalObj
+
 		case "defaults":
 			output = defaultCfg
 		default:
 			output = actualCfg
 		}
 
-		util.WriteYAMLResponse(w, output)
+		writeConfigResponse(w, r, output)
+	}
+}
+
+// configHashHandler exposes the SHA256 of the canonicalized config YAML, so that a fleet of
+// Cortex instances can be verified as running identical config by scraping a small, fixed-size
+// endpoint instead of fetching and diffing the full config on every instance.
+func configHashHandler(actualCfg interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		canonical, err := canonicalConfigYAML(actualCfg)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		sum := sha256.Sum256(canonical)
+		writeConfigResponse(w, r, map[string]string{"sha256": hex.EncodeToString(sum[:])})
+	}
+}
+
+// cortexFlags returns the flags Cortex was started with, keyed by flag name, in the form
+// expected by the Prometheus /api/v1/status/flags handler.
+func cortexFlags() map[string]string {
+	flags := map[string]string{}
+	flag.VisitAll(func(f *flag.Flag) {
+		flags[f.Name] = f.Value.String()
+	})
+	return flags
+}
+
+// prometheusVersion returns the build information of this binary in the shape expected by the
+// Prometheus /api/v1/status/buildinfo handler. It relies on github.com/prometheus/common/version,
+// which cmd/cortex populates with the ldflags set at build time.
+func prometheusVersion() *v1.PrometheusVersion {
+	return &v1.PrometheusVersion{
+		Version:   version.Version,
+		Revision:  version.Revision,
+		Branch:    version.Branch,
+		BuildUser: version.BuildUser,
+		BuildDate: version.BuildDate,
+		GoVersion: version.GoVersion,
+	}
+}
+
+// runtimeInfoFunc returns the RuntimeInfo retriever passed to v1.NewAPI, backing the
+// /api/v1/status/runtimeinfo endpoint.
+func runtimeInfoFunc(cfg Config, startTime time.Time) func() (v1.RuntimeInfo, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "<error retrieving current working directory>"
+	}
+
+	return func() (v1.RuntimeInfo, error) {
+		return v1.RuntimeInfo{
+			StartTime:        startTime,
+			CWD:              cwd,
+			GoroutineCount:   runtime.NumGoroutine(),
+			GOMAXPROCS:       runtime.GOMAXPROCS(0),
+			GOGC:             os.Getenv("GOGC"),
+			GODEBUG:          os.Getenv("GODEBUG"),
+			StorageRetention: cfg.StorageRetention,
+		}, nil
+	}
+}
+
+// prometheusConfigFunc synthesizes a config.Config from the parts of the Cortex configuration
+// that map onto Prometheus concepts, backing the /api/v1/status/config endpoint.
+func prometheusConfigFunc(cfg Config) func() config.Config {
+	return func() config.Config {
+		return config.Config{
+			GlobalConfig: config.GlobalConfig{
+				ExternalLabels: cfg.ExternalLabels,
+			},
+			ScrapeConfigs: cfg.ScrapeConfigs,
+		}
 	}
 }
 
@@ -233,6 +447,74 @@ func runtimeConfigHandler(runtimeCfgManager *runtimeconfig.Manager) http.Handler
 	}
 }
 
+// runtimeConfigReloadHandler forces the runtime config manager to re-read its source
+// immediately, mirroring Prometheus's POST /-/reload admin endpoint. This lets operators
+// relying on ConfigMap projections skip waiting for the next poll interval after applying a
+// change, and lets CI pipelines validate a runtime config change synchronously.
+func runtimeConfigReloadHandler(runtimeCfgManager *runtimeconfig.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := runtimeCfgManager.ForceReload()
+		switch {
+		case err == nil:
+			util.WriteTextResponse(w, "ok")
+		case err == runtimeconfig.ErrReloadDisabled:
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		default:
+			// The manager keeps serving the last known-good config; we only report the
+			// parse error back to the caller.
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+const (
+	corsAllowedMethods = "GET, POST, OPTIONS, DELETE"
+	corsAllowedHeaders = "Authorization, Content-Type, X-Scope-OrgID, X-Prometheus-Scrape-Timeout-Seconds"
+	corsExposedHeaders = "Result-Cache-Gen-Number"
+)
+
+// corsPreflightHandler answers an OPTIONS preflight request for any Prometheus API path with
+// the headers a browser needs before it will issue the real cross-origin request.
+func corsPreflightHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+	w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newCORSMiddleware reflects the request's Origin header back on the response, but only when
+// it matches allowedOrigin. A nil allowedOrigin matches no origin, and neither does the default
+// Config.CORSOrigin ("^$"), so by default no Access-Control-Allow-Origin header is set.
+// Access-Control-Expose-Headers is set on every response regardless.
+func newCORSMiddleware(allowedOrigin *regexp.Regexp) middleware.Interface {
+	return middleware.Func(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+
+			if origin := r.Header.Get("Origin"); origin != "" && allowedOrigin != nil && allowedOrigin.MatchString(origin) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	})
+}
+
+// registerStatusRoutes registers the Prometheus /api/v1/status/* GET endpoints under prefix on
+// router, all handled by handler (either the prom or legacy-prom router, depending on caller).
+// Pulled out of NewQuerierHandler so the route table itself can be exercised in a unit test
+// without having to construct the querier's full set of dependencies.
+func registerStatusRoutes(router *mux.Router, prefix string, handler http.Handler) {
+	for _, path := range []string{
+		"/api/v1/status/config",
+		"/api/v1/status/flags",
+		"/api/v1/status/runtimeinfo",
+		"/api/v1/status/buildinfo",
+		"/api/v1/status/tsdb",
+	} {
+		router.Path(prefix + path).Methods("GET").Handler(handler)
+	}
+}
+
 // NewQuerierHandler returns a HTTP handler that can be used by the querier service to
 // either register with the frontend worker query processor or with the external HTTP
 // server to fulfill the Prometheus query API.
@@ -243,8 +525,11 @@ func NewQuerierHandler(
 	distributor *distributor.Distributor,
 	tombstonesLoader *purger.TombstonesLoader,
 	reg prometheus.Registerer,
+	gatherer prometheus.Gatherer,
 	logger log.Logger,
 ) http.Handler {
+	startTime := time.Now()
+
 	// Prometheus histograms for requests to the querier.
 	querierRequestDuration := promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: "cortex",
@@ -278,8 +563,8 @@ func NewQuerierHandler(
 		errorTranslateQueryable{queryable}, // Translate errors to errors expected by API.
 		func(context.Context) v1.TargetRetriever { return &querier.DummyTargetRetriever{} },
 		func(context.Context) v1.AlertmanagerRetriever { return &querier.DummyAlertmanagerRetriever{} },
-		func() config.Config { return config.Config{} },
-		map[string]string{}, // TODO: include configuration flags
+		prometheusConfigFunc(cfg),
+		cortexFlags(),
 		v1.GlobalURLOptions{},
 		func(f http.HandlerFunc) http.HandlerFunc { return f },
 		nil,   // Only needed for admin APIs.
@@ -289,10 +574,9 @@ func NewQuerierHandler(
 		func(context.Context) v1.RulesRetriever { return &querier.DummyRulesRetriever{} },
 		0, 0, 0, // Remote read samples and concurrency limit.
 		regexp.MustCompile(".*"),
-		func() (v1.RuntimeInfo, error) { return v1.RuntimeInfo{}, errors.New("not implemented") },
-		&v1.PrometheusVersion{},
-		// This is used for the stats API which we should not support. Or find other ways to.
-		prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) { return nil, nil }),
+		runtimeInfoFunc(cfg, startTime),
+		prometheusVersion(),
+		gatherer,
 	)
 
 	router := mux.NewRouter()
@@ -307,7 +591,7 @@ func NewQuerierHandler(
 		InflightRequests: inflightRequests,
 	}
 	cacheGenHeaderMiddleware := getHTTPCacheGenNumberHeaderSetterMiddleware(tombstonesLoader)
-	middlewares := middleware.Merge(inst, cacheGenHeaderMiddleware)
+	middlewares := middleware.Merge(inst, cacheGenHeaderMiddleware, newCORSMiddleware(cfg.CORSOrigin))
 	router.Use(middlewares.Wrap)
 
 	// Define the prefixes for all routes
@@ -320,6 +604,12 @@ func NewQuerierHandler(
 	legacyPromRouter := route.New().WithPrefix(legacyPrefix + "/api/v1")
 	api.Register(legacyPromRouter)
 
+	// CORS preflight requests get their own catch-all OPTIONS route, registered ahead of the
+	// specific ones below: most of those don't accept OPTIONS, and the metadata and read routes
+	// accept any method and would otherwise take the preflight request.
+	router.PathPrefix(prefix + "/api/v1").Methods(http.MethodOptions).HandlerFunc(corsPreflightHandler)
+	router.PathPrefix(legacyPrefix + "/api/v1").Methods(http.MethodOptions).HandlerFunc(corsPreflightHandler)
+
 	// TODO(gotjosh): This custom handler is temporary until we're able to vendor the changes in:
 	// https://github.com/prometheus/prometheus/pull/7125/files
 	router.Path(prefix + "/api/v1/metadata").Handler(querier.MetadataHandler(distributor))
@@ -332,6 +622,10 @@ func NewQuerierHandler(
 	router.Path(prefix+"/api/v1/series").Methods("GET", "POST", "DELETE").Handler(promRouter)
 	router.Path(prefix + "/api/v1/metadata").Methods("GET").Handler(promRouter)
 
+	// Prometheus status endpoints, used by Grafana's data source diagnostics and other
+	// tooling that expects a Prometheus-compatible querier to expose them.
+	registerStatusRoutes(router, prefix, promRouter)
+
 	// TODO(gotjosh): This custom handler is temporary until we're able to vendor the changes in:
 	// https://github.com/prometheus/prometheus/pull/7125/files
 	router.Path(legacyPrefix + "/api/v1/metadata").Handler(querier.MetadataHandler(distributor))
@@ -344,6 +638,8 @@ func NewQuerierHandler(
 	router.Path(legacyPrefix+"/api/v1/series").Methods("GET", "POST", "DELETE").Handler(legacyPromRouter)
 	router.Path(legacyPrefix + "/api/v1/metadata").Methods("GET").Handler(legacyPromRouter)
 
+	registerStatusRoutes(router, legacyPrefix, legacyPromRouter)
+
 	// Add a middleware to extract the trace context and add a header.
 	handler := nethttp.MiddlewareFunc(opentracing.GlobalTracer(), router.ServeHTTP, nethttp.OperationNameFunc(func(r *http.Request) string {
 		return "internalQuerier"
@@ -352,3 +648,107 @@ func NewQuerierHandler(
 	// Track execution time.
 	return stats.NewWallTimeMiddleware().Wrap(handler)
 }
+
+// tenantMetricsLabel is the label cortex_* metrics are partitioned by internally; it's stripped
+// from the exposition served by TenantMetricsHandler so that a tenant sees the same shape of
+// output a dedicated single-tenant Prometheus would scrape from its own /metrics.
+const tenantMetricsLabel = "user"
+
+// TenantMetricsHandler serves a per-tenant view of the metrics exposed by gatherer: only the
+// series whose "user" label matches the caller's X-Scope-OrgID are returned, with that label
+// dropped from the output. This must only be mounted behind middleware that populates the
+// tenant ID in the request context (e.g. the standard auth middleware) - there is deliberately
+// no way to request another tenant's (or every tenant's) data through this handler. See
+// AdminTenantMetricsHandler for the unfiltered, admin-only equivalent.
+//
+// Note this handler has no ?format=json mode: an earlier revision supported one as an
+// unauthenticated alias for the admin-only raw dump, which defeated the tenant isolation above.
+// That mode has been removed rather than fixed - it is not reachable on this route under any
+// query parameter - so anything downstream still expecting JSON from /api/v1/user_metrics
+// should move to AdminTenantMetricsHandler's route instead.
+func TenantMetricsHandler(gatherer prometheus.Gatherer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, err := tenant.TenantID(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		metricFamilies, err := gatherer.Gather()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		contentType := expfmt.Negotiate(r.Header)
+		w.Header().Set("Content-Type", string(contentType))
+
+		enc := expfmt.NewEncoder(w, contentType)
+		for _, mf := range filterMetricFamiliesByTenant(metricFamilies, userID) {
+			if err := enc.Encode(mf); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	})
+}
+
+// AdminTenantMetricsHandler returns the raw, unfiltered dto.MetricFamily list for every tenant
+// as JSON, for programmatic consumption. It carries no authorization check of its own: it must
+// only ever be registered on an admin-only router (Cortex's internal server, not the
+// tenant-facing one) - never reachable via a query parameter on the tenant-facing
+// /api/v1/user_metrics route.
+func AdminTenantMetricsHandler(gatherer prometheus.Gatherer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metricFamilies, err := gatherer.Gather()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(metricFamilies); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// filterMetricFamiliesByTenant returns the subset of families that have at least one metric
+// with a "user" label matching userID, with that label stripped from the kept metrics.
+func filterMetricFamiliesByTenant(families []*dto.MetricFamily, userID string) []*dto.MetricFamily {
+	filtered := make([]*dto.MetricFamily, 0, len(families))
+
+	for _, mf := range families {
+		var kept []*dto.Metric
+
+		for _, m := range mf.Metric {
+			labels := make([]*dto.LabelPair, 0, len(m.Label))
+			matches := false
+
+			for _, l := range m.Label {
+				if l.GetName() == tenantMetricsLabel {
+					if l.GetValue() != userID {
+						continue
+					}
+					matches = true
+					continue
+				}
+				labels = append(labels, l)
+			}
+
+			if matches {
+				m.Label = labels
+				kept = append(kept, m)
+			}
+		}
+
+		if len(kept) == 0 {
+			continue
+		}
+
+		mf.Metric = kept
+		filtered = append(filtered, mf)
+	}
+
+	return filtered
+}
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/prometheus/pkg/labels"
+)
+
+func labelPair(name, value string) *dto.LabelPair {
+	return &dto.LabelPair{Name: &name, Value: &value}
+}
+
+func TestFilterMetricFamiliesByTenant(t *testing.T) {
+	name := "cortex_ingester_ingested_samples_total"
+	families := []*dto.MetricFamily{
+		{
+			Name: &name,
+			Metric: []*dto.Metric{
+				{Label: []*dto.LabelPair{labelPair("user", "tenant-a"), labelPair("route", "/push")}},
+				{Label: []*dto.LabelPair{labelPair("user", "tenant-b"), labelPair("route", "/push")}},
+			},
+		},
+	}
+
+	filtered := filterMetricFamiliesByTenant(families, "tenant-a")
+
+	if len(filtered) != 1 {
+		t.Fatalf("len(filtered) = %d, want 1", len(filtered))
+	}
+	if len(filtered[0].Metric) != 1 {
+		t.Fatalf("len(filtered[0].Metric) = %d, want 1", len(filtered[0].Metric))
+	}
+
+	kept := filtered[0].Metric[0]
+	for _, l := range kept.Label {
+		if l.GetName() == "user" {
+			t.Errorf("kept metric still has a %q label", "user")
+		}
+	}
+	if got := kept.Label[0].GetValue(); got != "/push" {
+		t.Errorf("remaining label value = %q, want %q", got, "/push")
+	}
+}
+
+func TestFilterMetricFamiliesByTenant_NoMatch(t *testing.T) {
+	name := "cortex_ingester_ingested_samples_total"
+	families := []*dto.MetricFamily{
+		{
+			Name: &name,
+			Metric: []*dto.Metric{
+				{Label: []*dto.LabelPair{labelPair("user", "tenant-b")}},
+			},
+		},
+	}
+
+	filtered := filterMetricFamiliesByTenant(families, "tenant-a")
+
+	if len(filtered) != 0 {
+		t.Errorf("len(filtered) = %d, want 0", len(filtered))
+	}
+}
+
+func TestConfigHashHandler(t *testing.T) {
+	cfg := map[string]string{"z": "zzz", "a": "aaa"}
+
+	canonical, err := canonicalConfigYAML(cfg)
+	if err != nil {
+		t.Fatalf("canonicalConfigYAML: %v", err)
+	}
+	wantSum := sha256.Sum256(canonical)
+	wantHash := hex.EncodeToString(wantSum[:])
+
+	req := httptest.NewRequest(http.MethodGet, "/config/hash", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+
+	configHashHandler(cfg)(rec, req)
+
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if body["sha256"] != wantHash {
+		t.Errorf("sha256 = %q, want %q", body["sha256"], wantHash)
+	}
+
+	// Key order in the input map must not change the hash: it's computed over the
+	// canonicalized (sorted-keys) form.
+	reordered := map[string]string{"a": "aaa", "z": "zzz"}
+	canonical2, err := canonicalConfigYAML(reordered)
+	if err != nil {
+		t.Fatalf("canonicalConfigYAML: %v", err)
+	}
+	if string(canonical) != string(canonical2) {
+		t.Errorf("canonical YAML differs for a map built with different key insertion order:\n%s\nvs\n%s", canonical, canonical2)
+	}
+}
+
+func TestConfigHandler_JSONModes(t *testing.T) {
+	type cfg struct {
+		A string `yaml:"a"`
+		B string `yaml:"b"`
+	}
+
+	actual := cfg{A: "actual-a", B: "actual-b"}
+	defaults := cfg{A: "default-a", B: "default-b"}
+
+	for _, mode := range []string{"diff", "canonical", "defaults", ""} {
+		t.Run(mode, func(t *testing.T) {
+			target := "/config"
+			if mode != "" {
+				target += "?mode=" + mode
+			}
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req.Header.Set("Accept", "application/json")
+			rec := httptest.NewRecorder()
+
+			configHandler(actual, defaults)(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+			}
+
+			var body interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("response is not valid JSON: %v (body: %s)", err, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestIndexPageContent_GetContent_Ordering(t *testing.T) {
+	pc := newIndexPageContent()
+
+	pc.AddSection("Dangerous", "Handle with care.", 10)
+	pc.AddSection("Admin", "Operational endpoints.", 0)
+
+	pc.AddLinkWithWeight("Admin", "/config", "Current config", 5)
+	pc.AddLinkWithWeight("Admin", "/-/reload", "Reload runtime config", 0)
+	pc.AddLink("Admin", "/zzz", "Default weight, sorts last among equals")
+
+	sections := pc.GetContent()
+
+	if len(sections) != 2 {
+		t.Fatalf("len(sections) = %d, want 2", len(sections))
+	}
+	if sections[0].Name != "Admin" || sections[1].Name != "Dangerous" {
+		t.Fatalf("sections in wrong order: %v, %v", sections[0].Name, sections[1].Name)
+	}
+	if sections[0].Description != "Operational endpoints." {
+		t.Errorf("section description = %q, want %q", sections[0].Description, "Operational endpoints.")
+	}
+
+	links := sections[0].Links
+	if len(links) != 3 {
+		t.Fatalf("len(links) = %d, want 3", len(links))
+	}
+	wantOrder := []string{"/-/reload", "/zzz", "/config"}
+	for i, want := range wantOrder {
+		if links[i].Path != want {
+			t.Errorf("links[%d].Path = %q, want %q (full order: %v)", i, links[i].Path, want, links)
+		}
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	for name, tc := range map[string]struct {
+		allowedOrigin *regexp.Regexp
+		origin        string
+		wantAllow     string
+	}{
+		"nil regexp denies everything": {
+			allowedOrigin: nil,
+			origin:        "http://example.com",
+			wantAllow:     "",
+		},
+		"deny-all default denies everything": {
+			allowedOrigin: regexp.MustCompile("^$"),
+			origin:        "http://example.com",
+			wantAllow:     "",
+		},
+		"matching origin is reflected back": {
+			allowedOrigin: regexp.MustCompile("^https://grafana\\.example\\.com$"),
+			origin:        "https://grafana.example.com",
+			wantAllow:     "https://grafana.example.com",
+		},
+		"non-matching origin is not reflected": {
+			allowedOrigin: regexp.MustCompile("^https://grafana\\.example\\.com$"),
+			origin:        "https://evil.example.com",
+			wantAllow:     "",
+		},
+		"no origin header": {
+			allowedOrigin: regexp.MustCompile(".*"),
+			origin:        "",
+			wantAllow:     "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			handler := newCORSMiddleware(tc.allowedOrigin).Wrap(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/query", nil)
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tc.wantAllow {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tc.wantAllow)
+			}
+			if got := rec.Header().Get("Access-Control-Expose-Headers"); got != corsExposedHeaders {
+				t.Errorf("Access-Control-Expose-Headers = %q, want %q", got, corsExposedHeaders)
+			}
+		})
+	}
+}
+
+func TestCortexFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.PanicOnError)
+	flag.CommandLine = fs
+	flag.String("my.test-flag", "default-value", "usage")
+
+	flags := cortexFlags()
+
+	if got := flags["my.test-flag"]; got != "default-value" {
+		t.Errorf(`flags["my.test-flag"] = %q, want %q`, got, "default-value")
+	}
+}
+
+func TestRuntimeInfoFunc(t *testing.T) {
+	cfg := Config{StorageRetention: "168h"}
+	startTime := time.Unix(0, 0)
+
+	info, err := runtimeInfoFunc(cfg, startTime)()
+	if err != nil {
+		t.Fatalf("runtimeInfoFunc: %v", err)
+	}
+
+	if !info.StartTime.Equal(startTime) {
+		t.Errorf("StartTime = %v, want %v", info.StartTime, startTime)
+	}
+	if info.StorageRetention != "168h" {
+		t.Errorf("StorageRetention = %q, want %q", info.StorageRetention, "168h")
+	}
+	if info.CWD == "" {
+		t.Error("CWD is empty")
+	}
+}
+
+func TestPrometheusConfigFunc(t *testing.T) {
+	externalLabels := labels.Labels{{Name: "cluster", Value: "test"}}
+	cfg := Config{ExternalLabels: externalLabels}
+
+	got := prometheusConfigFunc(cfg)()
+
+	if !got.GlobalConfig.ExternalLabels.Equal(externalLabels) {
+		t.Errorf("ExternalLabels = %v, want %v", got.GlobalConfig.ExternalLabels, externalLabels)
+	}
+}
+
+func TestPrometheusVersion(t *testing.T) {
+	v := prometheusVersion()
+	if v == nil {
+		t.Fatal("prometheusVersion() = nil")
+	}
+}
+
+func TestRegisterStatusRoutes(t *testing.T) {
+	router := mux.NewRouter()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	registerStatusRoutes(router, "/prometheus", handler)
+
+	for _, path := range []string{
+		"/prometheus/api/v1/status/config",
+		"/prometheus/api/v1/status/flags",
+		"/prometheus/api/v1/status/runtimeinfo",
+		"/prometheus/api/v1/status/buildinfo",
+		"/prometheus/api/v1/status/tsdb",
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		var match mux.RouteMatch
+		if !router.Match(req, &match) {
+			t.Errorf("%s does not resolve to any route", path)
+			continue
+		}
+		rec := httptest.NewRecorder()
+		match.Handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestCORSPreflightHandler(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/query", nil)
+	rec := httptest.NewRecorder()
+
+	corsPreflightHandler(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got != corsAllowedMethods {
+		t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, corsAllowedMethods)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Headers"); got != corsAllowedHeaders {
+		t.Errorf("Access-Control-Allow-Headers = %q, want %q", got, corsAllowedHeaders)
+	}
+}
@@ -0,0 +1,117 @@
+package runtimeconfig
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// ErrReloadDisabled is returned by Manager.ForceReload when the manager wasn't started with a
+// config file, so there is nothing to reload.
+var ErrReloadDisabled = fmt.Errorf("no runtime config file configured, can't reload")
+
+// Loader loads and parses the runtime config found at filename.
+type Loader func(filename string) (interface{}, error)
+
+// Config configures a runtime config Manager.
+type Config struct {
+	// ReloadPeriod is how often the config file is re-read in the background. Zero disables
+	// the periodic reload; ForceReload still works regardless.
+	ReloadPeriod time.Duration
+	LoadPath     string
+	Loader       Loader
+	Logger       log.Logger
+}
+
+// Manager reloads a runtime config file from disk - periodically, every ReloadPeriod, and
+// on-demand via ForceReload - and serves the latest successfully parsed value.
+type Manager struct {
+	cfg     Config
+	logger  log.Logger
+	current atomic.Value
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// NewRuntimeConfigManager creates a Manager, performs the initial load if a config file is
+// configured, and starts the periodic reload loop if cfg.ReloadPeriod is set.
+func NewRuntimeConfigManager(cfg Config) (*Manager, error) {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+
+	mgr := &Manager{
+		cfg:    cfg,
+		logger: logger,
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	if cfg.LoadPath != "" {
+		if err := mgr.loadConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.LoadPath != "" && cfg.ReloadPeriod > 0 {
+		go mgr.loop()
+	} else {
+		close(mgr.done)
+	}
+
+	return mgr, nil
+}
+
+// GetConfig returns the last successfully loaded config, or nil if none has been loaded yet.
+func (m *Manager) GetConfig() interface{} {
+	return m.current.Load()
+}
+
+// ForceReload re-reads and re-parses the config file immediately, without waiting for the next
+// scheduled poll. If the file is invalid, the error is returned and the previously loaded
+// config keeps serving - an invalid file never replaces a known-good one.
+func (m *Manager) ForceReload() error {
+	if m.cfg.LoadPath == "" {
+		return ErrReloadDisabled
+	}
+	return m.loadConfig()
+}
+
+// Stop terminates the periodic reload loop, if one was started, and waits for it to exit.
+func (m *Manager) Stop() {
+	close(m.quit)
+	<-m.done
+}
+
+func (m *Manager) loop() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.cfg.ReloadPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.loadConfig(); err != nil {
+				level.Error(m.logger).Log("msg", "failed to reload runtime config, keeping last known-good config", "path", m.cfg.LoadPath, "err", err)
+			}
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+func (m *Manager) loadConfig() error {
+	cfg, err := m.cfg.Loader(m.cfg.LoadPath)
+	if err != nil {
+		return fmt.Errorf("unable to load runtime config from %s: %w", m.cfg.LoadPath, err)
+	}
+
+	m.current.Store(cfg)
+	return nil
+}
@@ -0,0 +1,119 @@
+package runtimeconfig
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestManager_ForceReload(t *testing.T) {
+	t.Run("disabled when no load path is configured", func(t *testing.T) {
+		mgr, err := NewRuntimeConfigManager(Config{})
+		if err != nil {
+			t.Fatalf("NewRuntimeConfigManager: %v", err)
+		}
+		defer mgr.Stop()
+
+		if err := mgr.ForceReload(); !errors.Is(err, ErrReloadDisabled) {
+			t.Errorf("ForceReload() = %v, want ErrReloadDisabled", err)
+		}
+	})
+
+	t.Run("propagates a parse error without discarding the last good config", func(t *testing.T) {
+		calls := 0
+		mgr, err := NewRuntimeConfigManager(Config{
+			LoadPath: "/fake/path",
+			Loader: func(string) (interface{}, error) {
+				calls++
+				return "good-config", nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("NewRuntimeConfigManager: %v", err)
+		}
+		defer mgr.Stop()
+
+		mgr.cfg.Loader = func(string) (interface{}, error) {
+			return nil, errors.New("boom")
+		}
+
+		if err := mgr.ForceReload(); err == nil {
+			t.Fatal("ForceReload() = nil, want an error")
+		}
+
+		if got := mgr.GetConfig(); got != "good-config" {
+			t.Errorf("GetConfig() = %v, want unchanged %q", got, "good-config")
+		}
+	})
+
+	t.Run("reloads successfully", func(t *testing.T) {
+		current := "v1"
+		mgr, err := NewRuntimeConfigManager(Config{
+			LoadPath: "/fake/path",
+			Loader: func(string) (interface{}, error) {
+				return current, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("NewRuntimeConfigManager: %v", err)
+		}
+		defer mgr.Stop()
+
+		current = "v2"
+		if err := mgr.ForceReload(); err != nil {
+			t.Fatalf("ForceReload(): %v", err)
+		}
+		if got := mgr.GetConfig(); got != "v2" {
+			t.Errorf("GetConfig() = %v, want %q", got, "v2")
+		}
+	})
+}
+
+func TestManager_PeriodicReload(t *testing.T) {
+	var loads int64
+
+	mgr, err := NewRuntimeConfigManager(Config{
+		LoadPath:     "/fake/path",
+		ReloadPeriod: 5 * time.Millisecond,
+		Loader: func(string) (interface{}, error) {
+			n := atomic.AddInt64(&loads, 1)
+			return n, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRuntimeConfigManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt64(&loads) < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got := atomic.LoadInt64(&loads); got < 3 {
+		t.Fatalf("loads = %d, want at least 3 within the deadline - the periodic reload loop doesn't seem to be running", got)
+	}
+}
+
+func TestManager_NoPeriodicReloadWithoutReloadPeriod(t *testing.T) {
+	var loads int64
+
+	mgr, err := NewRuntimeConfigManager(Config{
+		LoadPath: "/fake/path",
+		Loader: func(string) (interface{}, error) {
+			atomic.AddInt64(&loads, 1)
+			return "v1", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRuntimeConfigManager: %v", err)
+	}
+	defer mgr.Stop()
+
+	time.Sleep(20 * time.Millisecond)
+
+	if got := atomic.LoadInt64(&loads); got != 1 {
+		t.Errorf("loads = %d, want exactly 1 (the initial load, no periodic reload)", got)
+	}
+}